@@ -1,6 +1,7 @@
 package retry
 
 import (
+	"context"
 	"crypto/sha1"
 	"errors"
 	"fmt"
@@ -16,6 +17,11 @@ type backoff struct {
 	worker          func(string, ...interface{}) error
 	RetryLimit      int
 	BackoffStrategy []int
+	Strategy        BackoffStrategy
+	JitterFunc      Jitter
+	ShouldRetry     func(error) bool
+	IsFailure       func(error) bool
+	Observer        RetryObserver
 }
 
 func NewBackoff(jobName string, workerFunc func(string, ...interface{}) error) *backoff {
@@ -26,16 +32,62 @@ func NewBackoff(jobName string, workerFunc func(string, ...interface{}) error) *
 	// Default backoff strategy in seconds
 	eb.BackoffStrategy = []int{0, 60, 600, 3600, 10800, 21600} // 0s, 1m, 10m, 1h, 3h, 6h
 	eb.RetryLimit = len(eb.BackoffStrategy)
+	eb.Observer = noopObserver{}
 	return eb
 }
 
+// WithStrategy swaps in a BackoffStrategy in place of the default
+// table-driven BackoffStrategy schedule.
+func (eb *backoff) WithStrategy(strategy BackoffStrategy) *backoff {
+	eb.Strategy = strategy
+	return eb
+}
+
+// WithJitter applies Jitter to every delay computed by the active strategy.
+func (eb *backoff) WithJitter(jitter Jitter) *backoff {
+	eb.JitterFunc = jitter
+	return eb
+}
+
+// WithShouldRetry marks an error as permanent: when it returns false the
+// job gives up immediately instead of spending the full RetryLimit on it.
+func (eb *backoff) WithShouldRetry(shouldRetry func(error) bool) *backoff {
+	eb.ShouldRetry = shouldRetry
+	return eb
+}
+
+// WithIsFailure excludes certain errors from the retry bookkeeping: when
+// it returns false the attempt is treated as a silent success.
+func (eb *backoff) WithIsFailure(isFailure func(error) bool) *backoff {
+	eb.IsFailure = isFailure
+	return eb
+}
+
+// WithObserver wires a RetryObserver into the retry lifecycle.
+func (eb *backoff) WithObserver(observer RetryObserver) *backoff {
+	eb.Observer = observer
+	return eb
+}
+
+func (eb *backoff) observer() RetryObserver {
+	if eb.Observer == nil {
+		return noopObserver{}
+	}
+	return eb.Observer
+}
+
+// getConn/putConn indirect through goworker's pool so tests can swap in a
+// fake *goworker.RedisConn without a live Redis.
+var getConn = goworker.GetConn
+var putConn = goworker.PutConn
+
 func (eb *backoff) WorkerFunc() func(string, ...interface{}) error {
 	return func(queue string, args ...interface{}) error {
-		conn, err := goworker.GetConn()
+		conn, err := getConn()
 		if err != nil {
 			return err
 		}
-		defer goworker.PutConn(conn)
+		defer putConn(conn)
 
 		retryKey := eb.retryKey(args)
 
@@ -51,31 +103,57 @@ func (eb *backoff) WorkerFunc() func(string, ...interface{}) error {
 			return err
 		}
 
+		eb.observer().OnAttempt(eb.jobName, retryAttempt, args)
+
 		// Expire the retry key so we don't leave it hanging
 		// (an hour after it was supposed to be removed)
 		err = eb.worker(queue, args...)
-		redis.Int(conn.Do("EXPIRE", retryKey, eb.retryDelay(retryAttempt)+3600))
+		redis.Int(conn.Do("EXPIRE", retryKey, int(eb.retryDelay(retryAttempt).Seconds())+3600))
 
 		// Success, just clear the retry key
 		if err == nil {
 			conn.Do("DEL", retryKey)
+			eb.observer().OnSuccess(eb.jobName, retryAttempt+1)
 			return nil
 		}
 
-		// If we've retried too many times, give up and return the err
-		if retryAttempt >= eb.RetryLimit {
+		// The caller doesn't consider this error a real failure at all;
+		// clear the retry key and don't propagate it.
+		if eb.IsFailure != nil && !eb.IsFailure(err) {
 			conn.Do("DEL", retryKey)
+			eb.observer().OnSuccess(eb.jobName, retryAttempt+1)
+			return nil
+		}
+
+		// The caller has marked this error as permanent; give up now
+		// instead of spending the full RetryLimit on it.
+		if !eb.shouldRetry(err) {
+			pushDead(conn.Conn, eb.jobName, queue, args, retryAttempt+1, err)
+			conn.Do("DEL", retryKey)
+			eb.observer().OnGiveUp(eb.jobName, retryAttempt+1, err)
+			return err
+		}
+
+		// If we've retried too many times, give up: record it on the
+		// dead-letter list for inspection and return the err
+		if retryAttempt >= eb.retryLimit() {
+			pushDead(conn.Conn, eb.jobName, queue, args, retryAttempt+1, err)
+			conn.Do("DEL", retryKey)
+			eb.observer().OnGiveUp(eb.jobName, retryAttempt+1, err)
 			return errors.New(fmt.Sprintf("Failed after %d attempts: %s", (retryAttempt + 1), err.Error()))
 		}
 
 		// Schedule the retry attempt
-		seconds := eb.retryDelay(retryAttempt)
-		if seconds <= 0 {
+		delay := eb.retryDelay(retryAttempt)
+		if retryAfter, ok := err.(RetryAfter); ok {
+			delay = retryAfter.RetryAfter()
+		}
+		eb.observer().OnRetryScheduled(eb.jobName, retryAttempt, delay, err)
+		if delay <= 0 {
 			// If there's no delay, just enqueue it
 			_, err = resque.Enqueue(conn.Conn, queue, eb.jobName, args...)
 		} else {
 			// Otherwise schedule it
-			delay := time.Duration(seconds) * time.Second
 			err = resque.EnqueueIn(conn.Conn, delay, queue, eb.jobName, args...)
 		}
 
@@ -92,15 +170,161 @@ func (eb *backoff) WorkerFunc() func(string, ...interface{}) error {
 	}
 }
 
-func (eb *backoff) retryDelay(attempt int) int {
-	if attempt > (len(eb.BackoffStrategy) - 1) {
-		attempt = len(eb.BackoffStrategy) - 1
+// withConn checks out a pooled Redis connection for the duration of fn
+// and returns it immediately afterward.
+func (eb *backoff) withConn(fn func(conn *goworker.RedisConn) error) error {
+	conn, err := getConn()
+	if err != nil {
+		return err
 	}
-	return eb.BackoffStrategy[attempt]
+	defer putConn(conn)
+	return fn(conn)
+}
+
+// WorkerFuncCtx is the context-aware counterpart to WorkerFunc. It runs
+// attempts in-process via a Config.Run loop instead of rescheduling
+// through resque, so ctx.Done() between attempts stops the loop, deletes
+// the retry key, and returns ctx.Err(). Each attempt checks out its own
+// connection rather than holding one for the whole (possibly hours-long)
+// loop.
+func (eb *backoff) WorkerFuncCtx() func(ctx context.Context, queue string, args ...interface{}) error {
+	return func(ctx context.Context, queue string, args ...interface{}) error {
+		retryKey := eb.retryKey(args)
+
+		if err := eb.withConn(func(conn *goworker.RedisConn) error {
+			_, err := conn.Do("SETNX", retryKey, -1)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		var lastAttempt int
+
+		// delayFor computes the delay before the next attempt, honoring
+		// a RetryAfter override on err the same way WorkerFunc does. It
+		// always uses lastAttempt (the Redis-tracked counter updated by
+		// each op() call below) rather than Config.Run's own loop
+		// counter, so the delay slept, the EXPIRE TTL, and the observer
+		// events all agree on the same attempt number.
+		delayFor := func(_ int, err error) time.Duration {
+			if retryAfter, ok := err.(RetryAfter); ok {
+				return retryAfter.RetryAfter()
+			}
+			return eb.retryDelay(lastAttempt)
+		}
+
+		// Config.Tries and retryLimit both count retries after the first
+		// attempt (WorkerFunc gives up once its 0-based retryAttempt
+		// reaches eb.retryLimit()); only clamp a negative retryLimit so it
+		// doesn't collide with the Unlimited sentinel.
+		tries := eb.retryLimit()
+		if tries < 0 {
+			tries = 0
+		}
+
+		cfg := Config{
+			Tries:       tries,
+			ShouldRetry: eb.shouldRetry,
+			RetryDelay:  delayFor,
+		}
+
+		runErr := cfg.Run(ctx, func(ctx context.Context) error {
+			var attemptErr error
+
+			err := eb.withConn(func(conn *goworker.RedisConn) error {
+				retryAttempt, err := redis.Int(conn.Do("INCR", retryKey))
+				if err != nil {
+					return err
+				}
+				lastAttempt = retryAttempt
+				eb.observer().OnAttempt(eb.jobName, retryAttempt, args)
+
+				attemptErr = eb.worker(queue, args...)
+				conn.Do("EXPIRE", retryKey, int(eb.retryDelay(retryAttempt).Seconds())+3600)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			if attemptErr == nil {
+				eb.observer().OnSuccess(eb.jobName, lastAttempt+1)
+				return nil
+			}
+			if eb.IsFailure != nil && !eb.IsFailure(attemptErr) {
+				eb.observer().OnSuccess(eb.jobName, lastAttempt+1)
+				return nil
+			}
+			if lastAttempt < eb.retryLimit() && eb.shouldRetry(attemptErr) {
+				eb.observer().OnRetryScheduled(eb.jobName, lastAttempt, delayFor(lastAttempt, attemptErr), attemptErr)
+			}
+			return attemptErr
+		})
+
+		// Retries were exhausted (not simply cancelled): record the
+		// dead-letter entry before clearing the key.
+		if runErr != nil && ctx.Err() == nil {
+			eb.withConn(func(conn *goworker.RedisConn) error {
+				return pushDead(conn.Conn, eb.jobName, queue, args, lastAttempt+1, runErr)
+			})
+			eb.observer().OnGiveUp(eb.jobName, lastAttempt+1, runErr)
+		}
+
+		eb.withConn(func(conn *goworker.RedisConn) error {
+			_, err := conn.Do("DEL", retryKey)
+			return err
+		})
+
+		return runErr
+	}
+}
+
+// retryLimit returns the registered Retryable's RetryLimit when one
+// exists for eb.jobName, falling back to the backoff's own RetryLimit.
+func (eb *backoff) retryLimit() int {
+	if r := lookupRetryable(eb.jobName); r != nil {
+		return r.RetryLimit()
+	}
+	return eb.RetryLimit
+}
+
+// shouldRetry returns the registered Retryable's decision when one
+// exists for eb.jobName, falling back to eb.ShouldRetry (or true, if
+// neither is set).
+func (eb *backoff) shouldRetry(err error) bool {
+	if r := lookupRetryable(eb.jobName); r != nil {
+		return r.ShouldRetry(err)
+	}
+	if eb.ShouldRetry != nil {
+		return eb.ShouldRetry(err)
+	}
+	return true
+}
+
+func (eb *backoff) retryDelay(attempt int) time.Duration {
+	if r := lookupRetryable(eb.jobName); r != nil {
+		return r.RetryDelay(attempt)
+	}
+
+	strategy := eb.Strategy
+	if strategy == nil {
+		strategy = ListBackoff(eb.BackoffStrategy)
+	}
+
+	delay := strategy.NextDelay(attempt)
+	if eb.JitterFunc != nil {
+		delay = eb.JitterFunc(delay)
+	}
+	return delay
 }
 
 func (eb *backoff) retryKey(args []interface{}) string {
-	parts := []string{"resque", "resque-retry", eb.jobName, eb.retryIdentifier(args)}
+	identifier := eb.retryIdentifier(args)
+	if r := lookupRetryable(eb.jobName); r != nil {
+		identifier = r.RetryIdentifier(args)
+	}
+
+	parts := []string{"resque", "resque-retry", eb.jobName, identifier}
 	return strings.Join(parts, ":")
 }
 
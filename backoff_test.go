@@ -0,0 +1,270 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/everalbum/goworker"
+)
+
+// fakeConn is a minimal in-memory redis.Conn standing in for a real Redis
+// connection, just enough to drive WorkerFunc/WorkerFuncCtx: SETNX/INCR/GET
+// on the retry counter, EXPIRE/TTL bookkeeping, DEL, and RPUSH onto the
+// dead-letter list.
+type fakeConn struct {
+	mu       sync.Mutex
+	counters map[string]int64
+	ttls     map[string]int
+	dead     map[string][][]byte
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{
+		counters: map[string]int64{},
+		ttls:     map[string]int{},
+		dead:     map[string][][]byte{},
+	}
+}
+
+func (c *fakeConn) Do(cmd string, args ...interface{}) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch cmd {
+	case "SETNX":
+		key := args[0].(string)
+		if _, ok := c.counters[key]; ok {
+			return int64(0), nil
+		}
+		c.counters[key] = int64(args[1].(int))
+		return int64(1), nil
+	case "INCR":
+		key := args[0].(string)
+		c.counters[key]++
+		return c.counters[key], nil
+	case "EXPIRE":
+		c.ttls[args[0].(string)] = args[1].(int)
+		return int64(1), nil
+	case "TTL":
+		return int64(c.ttls[args[0].(string)]), nil
+	case "DEL":
+		key := args[0].(string)
+		delete(c.counters, key)
+		delete(c.ttls, key)
+		return int64(1), nil
+	case "GET":
+		return c.counters[args[0].(string)], nil
+	case "RPUSH":
+		key := args[0].(string)
+		value, ok := args[1].([]byte)
+		if !ok {
+			value = []byte(fmt.Sprint(args[1]))
+		}
+		c.dead[key] = append(c.dead[key], value)
+		return int64(len(c.dead[key])), nil
+	default:
+		return nil, fmt.Errorf("fakeConn: unsupported command %q", cmd)
+	}
+}
+
+func (c *fakeConn) Close() error                      { return nil }
+func (c *fakeConn) Err() error                        { return nil }
+func (c *fakeConn) Send(string, ...interface{}) error { return nil }
+func (c *fakeConn) Flush() error                      { return nil }
+func (c *fakeConn) Receive() (interface{}, error)     { return nil, nil }
+
+// withFakeConn points getConn/putConn at a single shared fakeConn for the
+// duration of fn, restoring the originals afterward.
+func withFakeConn(t *testing.T, fn func(*fakeConn)) {
+	t.Helper()
+	conn := newFakeConn()
+
+	origGet, origPut := getConn, putConn
+	getConn = func() (*goworker.RedisConn, error) {
+		return &goworker.RedisConn{Conn: conn}, nil
+	}
+	putConn = func(*goworker.RedisConn) {}
+	defer func() { getConn, putConn = origGet, origPut }()
+
+	fn(conn)
+}
+
+// recordingObserver captures every lifecycle event fired during a test.
+type recordingObserver struct {
+	mu        sync.Mutex
+	attempts  []int
+	scheduled []int
+	giveUps   []int
+	successes []int
+}
+
+func (o *recordingObserver) OnAttempt(jobName string, attempt int, args []interface{}) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attempts = append(o.attempts, attempt)
+}
+
+func (o *recordingObserver) OnRetryScheduled(jobName string, attempt int, delay time.Duration, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.scheduled = append(o.scheduled, attempt)
+}
+
+func (o *recordingObserver) OnGiveUp(jobName string, attempts int, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.giveUps = append(o.giveUps, attempts)
+}
+
+func (o *recordingObserver) OnSuccess(jobName string, attempts int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.successes = append(o.successes, attempts)
+}
+
+func TestWorkerFunc_SuccessClearsRetryKeyAndFiresOnSuccess(t *testing.T) {
+	obs := &recordingObserver{}
+	eb := NewBackoff("test.success", func(queue string, args ...interface{}) error {
+		return nil
+	}).WithObserver(obs)
+
+	withFakeConn(t, func(conn *fakeConn) {
+		if err := eb.WorkerFunc()("queue", "a"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		if len(obs.successes) != 1 || obs.successes[0] != 1 {
+			t.Fatalf("expected one OnSuccess(1), got %v", obs.successes)
+		}
+		if len(conn.counters) != 0 {
+			t.Fatalf("expected retry key to be cleared, counters = %v", conn.counters)
+		}
+	})
+}
+
+func TestWorkerFunc_PermanentFailureGivesUpImmediately(t *testing.T) {
+	obs := &recordingObserver{}
+	wantErr := errors.New("permanent")
+	eb := NewBackoff("test.permanent", func(queue string, args ...interface{}) error {
+		return wantErr
+	}).WithShouldRetry(func(err error) bool { return false }).WithObserver(obs)
+
+	withFakeConn(t, func(conn *fakeConn) {
+		err := eb.WorkerFunc()("queue", "a")
+		if err != wantErr {
+			t.Fatalf("expected %v, got %v", wantErr, err)
+		}
+
+		if len(obs.giveUps) != 1 {
+			t.Fatalf("expected exactly one OnGiveUp, got %v", obs.giveUps)
+		}
+		if len(conn.dead["resque:retry:dead:test.permanent"]) != 1 {
+			t.Fatalf("expected a dead-letter entry, got %v", conn.dead)
+		}
+	})
+}
+
+func TestWorkerFunc_GivesUpAfterRetryLimitReached(t *testing.T) {
+	obs := &recordingObserver{}
+	wantErr := errors.New("transient")
+	eb := NewBackoff("test.exhausted", func(queue string, args ...interface{}) error {
+		return wantErr
+	}).WithObserver(obs)
+	eb.RetryLimit = 3
+
+	withFakeConn(t, func(conn *fakeConn) {
+		// The first attempt plus RetryLimit retries.
+		var lastErr error
+		for i := 0; i <= eb.RetryLimit; i++ {
+			lastErr = eb.WorkerFunc()("queue", "a")
+		}
+
+		if lastErr == nil {
+			t.Fatal("expected an error on the final attempt")
+		}
+		if len(obs.giveUps) != 1 || obs.giveUps[0] != eb.RetryLimit+1 {
+			t.Fatalf("expected OnGiveUp(%d), got %v", eb.RetryLimit+1, obs.giveUps)
+		}
+		if len(conn.dead["resque:retry:dead:test.exhausted"]) != 1 {
+			t.Fatalf("expected a dead-letter entry, got %v", conn.dead)
+		}
+		if len(obs.scheduled) != eb.RetryLimit {
+			t.Fatalf("expected %d OnRetryScheduled calls before giving up, got %v", eb.RetryLimit, obs.scheduled)
+		}
+	})
+}
+
+// TestWorkerFuncCtx_MatchesWorkerFuncAttemptCount is a regression test for
+// the Tries/RetryLimit unit mismatch: WorkerFuncCtx's in-process loop must
+// invoke the worker exactly as many times as WorkerFunc does before giving
+// up (the first attempt plus RetryLimit retries), not one fewer or extra.
+func TestWorkerFuncCtx_MatchesWorkerFuncAttemptCount(t *testing.T) {
+	var calls int
+	obs := &recordingObserver{}
+	eb := NewBackoff("test.ctx-count", func(queue string, args ...interface{}) error {
+		calls++
+		return errors.New("transient")
+	}).WithStrategy(Constant(0)).WithObserver(obs)
+	eb.RetryLimit = 3
+
+	withFakeConn(t, func(conn *fakeConn) {
+		err := eb.WorkerFuncCtx()(context.Background(), "queue", "a")
+		if err == nil {
+			t.Fatal("expected an error after exhausting retries")
+		}
+		if calls != eb.RetryLimit+1 {
+			t.Fatalf("expected %d worker invocations, got %d", eb.RetryLimit+1, calls)
+		}
+		if len(obs.giveUps) != 1 || obs.giveUps[0] != eb.RetryLimit+1 {
+			t.Fatalf("expected OnGiveUp(%d), got %v", eb.RetryLimit+1, obs.giveUps)
+		}
+		if len(conn.dead["resque:retry:dead:test.ctx-count"]) != 1 {
+			t.Fatalf("expected a dead-letter entry, got %v", conn.dead)
+		}
+	})
+}
+
+func TestWorkerFuncCtx_SuccessFiresOnSuccess(t *testing.T) {
+	obs := &recordingObserver{}
+	eb := NewBackoff("test.ctx-success", func(queue string, args ...interface{}) error {
+		return nil
+	}).WithObserver(obs)
+
+	withFakeConn(t, func(conn *fakeConn) {
+		if err := eb.WorkerFuncCtx()(context.Background(), "queue", "a"); err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		if len(obs.successes) != 1 {
+			t.Fatalf("expected one OnSuccess, got %v", obs.successes)
+		}
+		if len(conn.counters) != 0 {
+			t.Fatalf("expected retry key to be cleared, counters = %v", conn.counters)
+		}
+	})
+}
+
+func TestWorkerFuncCtx_CancelledContextStopsLoop(t *testing.T) {
+	var calls int
+	ctx, cancel := context.WithCancel(context.Background())
+	eb := NewBackoff("test.ctx-cancel", func(queue string, args ...interface{}) error {
+		calls++
+		cancel()
+		return errors.New("transient")
+	}).WithStrategy(Constant(time.Hour))
+
+	withFakeConn(t, func(conn *fakeConn) {
+		if err := eb.WorkerFuncCtx()(ctx, "queue", "a"); err != context.Canceled {
+			t.Fatalf("expected context.Canceled, got %v", err)
+		}
+		if calls != 1 {
+			t.Fatalf("expected exactly 1 attempt before cancellation, got %d", calls)
+		}
+		if len(conn.dead["resque:retry:dead:test.ctx-cancel"]) != 0 {
+			t.Fatalf("expected no dead-letter entry for a cancelled run, got %v", conn.dead)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+package retry
+
+import (
+	"context"
+	"time"
+)
+
+// Unlimited, passed as Config.Tries, retries forever (subject to ctx and
+// MaxElapsedTime). Tries == 0 gives up after the first attempt, matching
+// backoff.RetryLimit's zero semantics; Tries > 0 allows that many
+// retries after the first attempt.
+const Unlimited = -1
+
+// Config governs a context-aware, in-process retry loop run via Run. It
+// covers the same ground as backoff (Tries, RetryDelay, ShouldRetry) but,
+// unlike backoff's WorkerFunc, blocks and sleeps between attempts instead
+// of rescheduling through resque, so a cancelled ctx can stop the loop
+// immediately instead of leaving a retry scheduled in Redis.
+type Config struct {
+	Tries          int
+	RetryDelay     func(attempt int, err error) time.Duration
+	ShouldRetry    func(error) bool
+	MaxElapsedTime time.Duration
+}
+
+// Run executes op, retrying according to c until it succeeds, ctx is
+// done, Tries is exhausted, or MaxElapsedTime has elapsed since Run was
+// called.
+func (c Config) Run(ctx context.Context, op func(ctx context.Context) error) error {
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		err := op(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if c.ShouldRetry != nil && !c.ShouldRetry(err) {
+			return err
+		}
+
+		if c.Tries != Unlimited && attempt >= c.Tries {
+			return err
+		}
+
+		if c.MaxElapsedTime > 0 && time.Since(start) >= c.MaxElapsedTime {
+			return err
+		}
+
+		delay := time.Duration(0)
+		if c.RetryDelay != nil {
+			delay = c.RetryDelay(attempt, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConfigRun_TriesExhausted(t *testing.T) {
+	calls := 0
+	cfg := Config{
+		Tries:      2,
+		RetryDelay: func(attempt int, err error) time.Duration { return time.Millisecond },
+	}
+
+	wantErr := errors.New("boom")
+	err := cfg.Run(context.Background(), func(ctx context.Context) error {
+		calls++
+		return wantErr
+	})
+
+	if err != wantErr {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if calls != 3 {
+		t.Fatalf("expected 3 attempts (1 initial + 2 retries), got %d", calls)
+	}
+}
+
+func TestConfigRun_TriesZeroGivesUpImmediately(t *testing.T) {
+	calls := 0
+	cfg := Config{Tries: 0}
+
+	if err := cfg.Run(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errors.New("boom")
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 attempt for Tries=0, got %d", calls)
+	}
+}
+
+func TestConfigRun_ShouldRetryShortCircuits(t *testing.T) {
+	calls := 0
+	cfg := Config{
+		Tries:       Unlimited,
+		ShouldRetry: func(err error) bool { return false },
+	}
+
+	if err := cfg.Run(context.Background(), func(ctx context.Context) error {
+		calls++
+		return errors.New("permanent")
+	}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 attempt when ShouldRetry is false, got %d", calls)
+	}
+}
+
+func TestConfigRun_CancelledMidSleep(t *testing.T) {
+	calls := 0
+	cfg := Config{
+		Tries:      Unlimited,
+		RetryDelay: func(attempt int, err error) time.Duration { return time.Second },
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := cfg.Run(ctx, func(ctx context.Context) error {
+		calls++
+		return errors.New("transient")
+	})
+
+	if err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if calls != 1 {
+		t.Fatalf("expected exactly 1 attempt before cancellation, got %d", calls)
+	}
+}
+
+func TestConfigRun_SucceedsWithoutRetry(t *testing.T) {
+	cfg := Config{Tries: 3}
+
+	if err := cfg.Run(context.Background(), func(ctx context.Context) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+}
@@ -0,0 +1,170 @@
+package retry
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/everalbum/go-resque"
+	"github.com/garyburd/redigo/redis"
+)
+
+// DeadJob is a structured record of a job that exhausted its RetryLimit
+// or failed with an error ShouldRetry rejected, pushed onto a per-job-name "resque:retry:dead" list compatible with
+// the resque-retry ecosystem's "failed" queue.
+type DeadJob struct {
+	ID        string        `json:"id"`
+	JobName   string        `json:"job_name"`
+	Queue     string        `json:"queue"`
+	Args      []interface{} `json:"args"`
+	Attempts  int           `json:"attempts"`
+	LastError string        `json:"last_error"`
+	FailedAt  time.Time     `json:"failed_at"`
+}
+
+// PendingRetry describes a job currently scheduled for retry: the key
+// tracking its attempt count, the attempt it's on, and roughly how long
+// until it fires again.
+type PendingRetry struct {
+	Key      string
+	Attempt  int
+	NextFire time.Duration
+}
+
+func deadListKey(jobName string) string {
+	return strings.Join([]string{"resque", "retry", "dead", jobName}, ":")
+}
+
+// pushDead records a DeadJob for jobName after it has exhausted its
+// RetryLimit or failed with an error ShouldRetry rejected.
+func pushDead(conn redis.Conn, jobName, queue string, args []interface{}, attempts int, lastErr error) error {
+	job := DeadJob{
+		ID:        fmt.Sprintf("%s:%d", jobName, time.Now().UnixNano()),
+		JobName:   jobName,
+		Queue:     queue,
+		Args:      args,
+		Attempts:  attempts,
+		LastError: lastErr.Error(),
+		FailedAt:  time.Now(),
+	}
+
+	encoded, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Do("RPUSH", deadListKey(jobName), encoded)
+	return err
+}
+
+// ListDead returns every DeadJob recorded for jobName, oldest first.
+func ListDead(conn redis.Conn, jobName string) ([]DeadJob, error) {
+	raw, err := redis.Strings(conn.Do("LRANGE", deadListKey(jobName), 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]DeadJob, len(raw))
+	for i, entry := range raw {
+		if err := json.Unmarshal([]byte(entry), &jobs[i]); err != nil {
+			return nil, err
+		}
+	}
+	return jobs, nil
+}
+
+// RequeueDead removes the dead job with the given id and re-enqueues it
+// onto its original queue. The id's job name prefix (see pushDead) is
+// used to find the list it lives on.
+func RequeueDead(conn redis.Conn, id string) error {
+	jobName := strings.SplitN(id, ":", 2)[0]
+
+	jobs, err := ListDead(conn, jobName)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.ID != id {
+			continue
+		}
+
+		encoded, err := json.Marshal(job)
+		if err != nil {
+			return err
+		}
+		if _, err := conn.Do("LREM", deadListKey(jobName), 1, encoded); err != nil {
+			return err
+		}
+
+		_, err = resque.Enqueue(conn, job.Queue, job.JobName, job.Args...)
+		return err
+	}
+
+	return fmt.Errorf("retry: no dead job found with id %q", id)
+}
+
+// ClearDead deletes every recorded DeadJob for jobName.
+func ClearDead(conn redis.Conn, jobName string) error {
+	_, err := conn.Do("DEL", deadListKey(jobName))
+	return err
+}
+
+// PendingRetries scans the resque:resque-retry:jobName:* keys via SCAN
+// (rather than KEYS, which blocks a single-threaded Redis for the
+// duration of the scan) and reports the current attempt count and
+// approximate time to next fire for each job still in its retry window.
+func PendingRetries(conn redis.Conn, jobName string) ([]PendingRetry, error) {
+	pattern := strings.Join([]string{"resque", "resque-retry", jobName, "*"}, ":")
+
+	var pending []PendingRetry
+	cursor := "0"
+	for {
+		reply, err := redis.Values(conn.Do("SCAN", cursor, "MATCH", pattern, "COUNT", 100))
+		if err != nil {
+			return nil, err
+		}
+
+		cursor, err = redis.String(reply[0], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		keys, err := redis.Strings(reply[1], nil)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			attempt, err := redis.Int(conn.Do("GET", key))
+			if err != nil {
+				continue
+			}
+
+			ttl, err := redis.Int(conn.Do("TTL", key))
+			if err != nil {
+				continue
+			}
+
+			// EXPIRE is set to the scheduled delay plus a 3600s grace
+			// period (see backoff.WorkerFunc), so subtract it back out.
+			nextFire := ttl - 3600
+			if nextFire < 0 {
+				nextFire = 0
+			}
+
+			pending = append(pending, PendingRetry{
+				Key:      key,
+				Attempt:  attempt,
+				NextFire: time.Duration(nextFire) * time.Second,
+			})
+		}
+
+		if cursor == "0" {
+			break
+		}
+	}
+
+	return pending, nil
+}
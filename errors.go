@@ -0,0 +1,12 @@
+package retry
+
+import "time"
+
+// RetryAfter is implemented by errors that know how long to wait before
+// the next attempt, overriding whatever delay the active BackoffStrategy
+// would otherwise compute. This mirrors an HTTP 429 / Retry-After
+// response, or Prometheus's RecoverableError{retryAfter}.
+type RetryAfter interface {
+	error
+	RetryAfter() time.Duration
+}
@@ -0,0 +1,20 @@
+package retry
+
+import "time"
+
+// RetryObserver receives lifecycle events for a backoff's retries.
+type RetryObserver interface {
+	OnAttempt(jobName string, attempt int, args []interface{})
+	OnRetryScheduled(jobName string, attempt int, delay time.Duration, err error)
+	OnGiveUp(jobName string, attempts int, err error)
+	OnSuccess(jobName string, attempts int)
+}
+
+// noopObserver is the default RetryObserver until a caller sets one with
+// WithObserver.
+type noopObserver struct{}
+
+func (noopObserver) OnAttempt(jobName string, attempt int, args []interface{})                    {}
+func (noopObserver) OnRetryScheduled(jobName string, attempt int, delay time.Duration, err error) {}
+func (noopObserver) OnGiveUp(jobName string, attempts int, err error)                             {}
+func (noopObserver) OnSuccess(jobName string, attempts int)                                       {}
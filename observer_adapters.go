@@ -0,0 +1,98 @@
+package retry
+
+import (
+	"log"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LoggerObserver is a RetryObserver that writes each lifecycle event to
+// a *log.Logger, useful for local development or as a fallback when
+// nothing richer is wired up.
+type LoggerObserver struct {
+	*log.Logger
+}
+
+func NewLoggerObserver(logger *log.Logger) LoggerObserver {
+	return LoggerObserver{Logger: logger}
+}
+
+func (o LoggerObserver) OnAttempt(jobName string, attempt int, args []interface{}) {
+	o.Printf("retry: %s attempt %d args=%v", jobName, attempt, args)
+}
+
+func (o LoggerObserver) OnRetryScheduled(jobName string, attempt int, delay time.Duration, err error) {
+	o.Printf("retry: %s scheduling retry %d in %s: %s", jobName, attempt, delay, err)
+}
+
+func (o LoggerObserver) OnGiveUp(jobName string, attempts int, err error) {
+	o.Printf("retry: %s gave up after %d attempts: %s", jobName, attempts, err)
+}
+
+func (o LoggerObserver) OnSuccess(jobName string, attempts int) {
+	o.Printf("retry: %s succeeded after %d attempts", jobName, attempts)
+}
+
+// PrometheusObserver is a RetryObserver that records counters for
+// attempts, retries, and give-ups, plus a histogram of retry delays, all
+// labeled by job name.
+type PrometheusObserver struct {
+	attempts    *prometheus.CounterVec
+	retries     *prometheus.CounterVec
+	giveUps     *prometheus.CounterVec
+	successes   *prometheus.CounterVec
+	retryDelays *prometheus.HistogramVec
+}
+
+// NewPrometheusObserver builds a PrometheusObserver and registers its
+// collectors with reg.
+func NewPrometheusObserver(reg prometheus.Registerer) (*PrometheusObserver, error) {
+	o := &PrometheusObserver{
+		attempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goworker_retry_attempts_total",
+			Help: "Number of times a job's WorkerFunc was invoked.",
+		}, []string{"job_name"}),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goworker_retry_retries_scheduled_total",
+			Help: "Number of times a retry was scheduled for a job.",
+		}, []string{"job_name"}),
+		giveUps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goworker_retry_give_ups_total",
+			Help: "Number of times a job exhausted its RetryLimit.",
+		}, []string{"job_name"}),
+		successes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "goworker_retry_successes_total",
+			Help: "Number of times a job eventually succeeded.",
+		}, []string{"job_name"}),
+		retryDelays: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name: "goworker_retry_delay_seconds",
+			Help: "Computed delay before a scheduled retry, in seconds.",
+		}, []string{"job_name"}),
+	}
+
+	for _, c := range []prometheus.Collector{o.attempts, o.retries, o.giveUps, o.successes, o.retryDelays} {
+		if err := reg.Register(c); err != nil {
+			return nil, err
+		}
+	}
+
+	return o, nil
+}
+
+func (o *PrometheusObserver) OnAttempt(jobName string, attempt int, args []interface{}) {
+	o.attempts.WithLabelValues(jobName).Inc()
+}
+
+func (o *PrometheusObserver) OnRetryScheduled(jobName string, attempt int, delay time.Duration, err error) {
+	o.retries.WithLabelValues(jobName).Inc()
+	o.retryDelays.WithLabelValues(jobName).Observe(delay.Seconds())
+}
+
+func (o *PrometheusObserver) OnGiveUp(jobName string, attempts int, err error) {
+	o.giveUps.WithLabelValues(jobName).Inc()
+}
+
+func (o *PrometheusObserver) OnSuccess(jobName string, attempts int) {
+	o.successes.WithLabelValues(jobName).Inc()
+}
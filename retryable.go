@@ -0,0 +1,37 @@
+package retry
+
+import (
+	"sync"
+	"time"
+)
+
+// Retryable lets a job payload declare its own retry policy, overriding
+// whatever was configured on the backoff at NewBackoff time. Register
+// one per job name with RegisterRetryable; WorkerFunc and WorkerFuncCtx
+// consult it for the retry schedule, the retry-key identifier, and the
+// retry-vs-fail decision, falling back to the backoff's own fields when
+// nothing is registered for that job name.
+type Retryable interface {
+	RetryLimit() int
+	RetryDelay(attempt int) time.Duration
+	RetryIdentifier(args []interface{}) string
+	ShouldRetry(err error) bool
+}
+
+var (
+	retryablesMu sync.RWMutex
+	retryables   = map[string]Retryable{}
+)
+
+// RegisterRetryable associates jobName with r.
+func RegisterRetryable(jobName string, r Retryable) {
+	retryablesMu.Lock()
+	defer retryablesMu.Unlock()
+	retryables[jobName] = r
+}
+
+func lookupRetryable(jobName string) Retryable {
+	retryablesMu.RLock()
+	defer retryablesMu.RUnlock()
+	return retryables[jobName]
+}
@@ -0,0 +1,104 @@
+package retry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// BackoffStrategy computes the delay before the next retry attempt.
+// attempt is the 0-indexed attempt counter already tracked in the retry
+// key, so NextDelay(0) is the delay scheduled after the first failure.
+type BackoffStrategy interface {
+	NextDelay(attempt int) time.Duration
+}
+
+// ListBackoff replays a fixed, table-driven schedule of delays, clamping
+// to the last entry once attempt runs past the end of the list. It is
+// the strategy NewBackoff falls back to when no Strategy is set, driven
+// by the BackoffStrategy field for backward compatibility.
+type ListBackoff []int
+
+func (l ListBackoff) NextDelay(attempt int) time.Duration {
+	if attempt < 0 {
+		attempt = 0
+	}
+	if attempt > len(l)-1 {
+		attempt = len(l) - 1
+	}
+	return time.Duration(l[attempt]) * time.Second
+}
+
+// Fibonacci grows the delay along the Fibonacci sequence, scaled by
+// Period and capped at MaxInterval when MaxInterval is non-zero.
+type Fibonacci struct {
+	Period      time.Duration
+	MaxInterval time.Duration
+}
+
+func (f Fibonacci) NextDelay(attempt int) time.Duration {
+	a, b := 0, 1
+	for i := 0; i < attempt; i++ {
+		a, b = b, a+b
+	}
+	delay := time.Duration(a) * f.Period
+	if f.MaxInterval > 0 && delay > f.MaxInterval {
+		return f.MaxInterval
+	}
+	return delay
+}
+
+// Constant returns the same delay for every attempt.
+type Constant time.Duration
+
+func (c Constant) NextDelay(attempt int) time.Duration {
+	return time.Duration(c)
+}
+
+// Linear grows the delay linearly with the attempt number: Period * attempt.
+type Linear struct {
+	Period time.Duration
+}
+
+func (l Linear) NextDelay(attempt int) time.Duration {
+	return l.Period * time.Duration(attempt)
+}
+
+// Exponential grows the delay as Period * 2^attempt, capped at
+// MaxInterval when MaxInterval is non-zero.
+type Exponential struct {
+	Period      time.Duration
+	MaxInterval time.Duration
+}
+
+func (e Exponential) NextDelay(attempt int) time.Duration {
+	delay := e.Period * time.Duration(math.Pow(2, float64(attempt)))
+	if e.MaxInterval > 0 && delay > e.MaxInterval {
+		return e.MaxInterval
+	}
+	return delay
+}
+
+// Jitter perturbs a delay computed by a BackoffStrategy so that many
+// jobs of the same class failing together don't all re-enqueue at
+// exactly the same instant.
+type Jitter func(delay time.Duration) time.Duration
+
+// FullJitter picks a delay uniformly at random from [0, delay), the
+// "full jitter" approach described in AWS's backoff/jitter blog post.
+func FullJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+	return time.Duration(rand.Int63n(int64(delay)))
+}
+
+// EqualJitter picks a delay uniformly at random from [delay/2, delay],
+// keeping at least half of the computed backoff.
+func EqualJitter(delay time.Duration) time.Duration {
+	if delay <= 0 {
+		return delay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half+1)))
+}
@@ -0,0 +1,124 @@
+package retry
+
+import (
+	"testing"
+	"time"
+)
+
+func TestListBackoff(t *testing.T) {
+	l := ListBackoff{0, 60, 600}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, 60 * time.Second},
+		{2, 600 * time.Second},
+		{5, 600 * time.Second}, // clamps to the last entry
+		{-1, 0},                // clamps to the first entry
+	}
+
+	for _, c := range cases {
+		if got := l.NextDelay(c.attempt); got != c.want {
+			t.Errorf("NextDelay(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestConstant(t *testing.T) {
+	c := Constant(5 * time.Second)
+	for _, attempt := range []int{0, 1, 10} {
+		if got := c.NextDelay(attempt); got != 5*time.Second {
+			t.Errorf("NextDelay(%d) = %v, want 5s", attempt, got)
+		}
+	}
+}
+
+func TestLinear(t *testing.T) {
+	l := Linear{Period: time.Second}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{3, 3 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := l.NextDelay(c.attempt); got != c.want {
+			t.Errorf("NextDelay(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestExponential(t *testing.T) {
+	e := Exponential{Period: time.Second, MaxInterval: 10 * time.Second}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{10, 10 * time.Second}, // capped by MaxInterval
+	}
+
+	for _, c := range cases {
+		if got := e.NextDelay(c.attempt); got != c.want {
+			t.Errorf("NextDelay(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestFibonacci(t *testing.T) {
+	f := Fibonacci{Period: time.Second}
+
+	cases := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 0},
+		{1, time.Second},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 3 * time.Second},
+		{5, 5 * time.Second},
+	}
+
+	for _, c := range cases {
+		if got := f.NextDelay(c.attempt); got != c.want {
+			t.Errorf("NextDelay(%d) = %v, want %v", c.attempt, got, c.want)
+		}
+	}
+}
+
+func TestFullJitter(t *testing.T) {
+	delay := 100 * time.Millisecond
+	for i := 0; i < 1000; i++ {
+		if got := FullJitter(delay); got < 0 || got >= delay {
+			t.Fatalf("FullJitter(%v) = %v, want in [0, %v)", delay, got, delay)
+		}
+	}
+}
+
+func TestEqualJitter(t *testing.T) {
+	delay := 100 * time.Millisecond
+	for i := 0; i < 1000; i++ {
+		if got := EqualJitter(delay); got < delay/2 || got > delay {
+			t.Fatalf("EqualJitter(%v) = %v, want in [%v, %v]", delay, got, delay/2, delay)
+		}
+	}
+}
+
+func TestJitterZeroDelay(t *testing.T) {
+	if got := FullJitter(0); got != 0 {
+		t.Errorf("FullJitter(0) = %v, want 0", got)
+	}
+	if got := EqualJitter(0); got != 0 {
+		t.Errorf("EqualJitter(0) = %v, want 0", got)
+	}
+}